@@ -97,32 +97,72 @@ type Manager struct {
 	done     chan struct{}
 	restored bool
 
+	// lastUpdate records the arguments of the most recent call to Update, so
+	// that a remote GroupLoader's Watch callback can trigger a reload of the
+	// same identifiers without the caller having to track them itself.
+	lastUpdate lastUpdateArgs
+
+	// watchMtx guards the per-scheme watch goroutines started for
+	// ManagerOptions.GroupLoaders entries implementing Watcher. It is
+	// separate from mtx because reconcileWatches is called from inside
+	// Update, which already holds mtx.
+	watchMtx    sync.Mutex
+	watchCancel map[string]context.CancelFunc
+	watchedIDs  map[string]string // scheme -> "\x00"-joined identifiers currently being watched
+
+	groupLoadErrors *prometheus.CounterVec
+
 	logger *slog.Logger
 }
 
+// lastUpdateArgs is a snapshot of the parameters passed to Manager.Update,
+// kept so Manager can re-run LoadGroups when a watched remote source changes.
+type lastUpdateArgs struct {
+	interval               time.Duration
+	files                  []string
+	externalLabels         labels.Labels
+	externalURL            string
+	groupEvalIterationFunc GroupEvalIterationFunc
+}
+
 // NotifyFunc sends notifications about a set of alerts generated by the given expression.
 type NotifyFunc func(ctx context.Context, expr string, alerts ...*Alert)
 
 // ManagerOptions bundles options for the Manager.
 type ManagerOptions struct {
-	ExternalURL               *url.URL
-	QueryFunc                 QueryFunc
-	NotifyFunc                NotifyFunc
-	Context                   context.Context
-	Appendable                storage.Appendable
-	Queryable                 storage.Queryable
-	Logger                    *slog.Logger
-	Registerer                prometheus.Registerer
-	OutageTolerance           time.Duration
-	ForGracePeriod            time.Duration
-	ResendDelay               time.Duration
-	GroupLoader               GroupLoader
+	ExternalURL     *url.URL
+	QueryFunc       QueryFunc
+	NotifyFunc      NotifyFunc
+	Context         context.Context
+	Appendable      storage.Appendable
+	Queryable       storage.Queryable
+	Logger          *slog.Logger
+	Registerer      prometheus.Registerer
+	OutageTolerance time.Duration
+	ForGracePeriod  time.Duration
+	ResendDelay     time.Duration
+	GroupLoader     GroupLoader
+	// GroupLoaders dispatches loading of a rule group identifier to a
+	// scheme-specific GroupLoader, keyed by URL scheme (e.g. "http", "s3").
+	// Identifiers with no scheme, or a scheme not present in this map, fall
+	// back to GroupLoader, preserving plain-file behavior.
+	GroupLoaders              map[string]GroupLoader
 	DefaultRuleQueryOffset    func() time.Duration
 	MaxConcurrentEvals        int64
 	ConcurrentEvalsEnabled    bool
 	RuleConcurrencyController RuleConcurrencyController
 	RuleDependencyController  RuleDependencyController
 
+	// TenantConcurrencyLimit returns the maximum number of rules that may be
+	// evaluated concurrently on behalf of a single tenant. It is only
+	// consulted by concurrency controllers that are tenant-aware, such as
+	// MultitenantConcurrencyController.
+	TenantConcurrencyLimit func(tenantID string) int64
+	// TenantIDFromContext extracts the tenant identity a rule evaluation is
+	// being performed for from ctx. The default implementation treats every
+	// evaluation as belonging to the same, single tenant.
+	TenantIDFromContext func(ctx context.Context) (string, error)
+
 	Metrics *Metrics
 }
 
@@ -141,10 +181,23 @@ func NewManager(o *ManagerOptions) *Manager {
 		o.Logger = promslog.NewNopLogger()
 	}
 
+	if o.TenantIDFromContext == nil {
+		o.TenantIDFromContext = func(context.Context) (string, error) { return "", nil }
+	}
+
 	if o.RuleConcurrencyController == nil {
-		if o.ConcurrentEvalsEnabled {
+		switch {
+		case o.TenantConcurrencyLimit != nil:
+			ctx := o.Context
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			o.RuleConcurrencyController = NewMultitenantConcurrencyController(
+				ctx, o.Registerer, o.MaxConcurrentEvals, o.TenantConcurrencyLimit, o.TenantIDFromContext, o.Logger,
+			)
+		case o.ConcurrentEvalsEnabled:
 			o.RuleConcurrencyController = newRuleConcurrencyController(o.MaxConcurrentEvals, o.Logger)
-		} else {
+		default:
 			o.RuleConcurrencyController = sequentialRuleEvalController{}
 		}
 	}
@@ -153,12 +206,23 @@ func NewManager(o *ManagerOptions) *Manager {
 		o.RuleDependencyController = ruleDependencyController{}
 	}
 
+	groupLoadErrors := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "prometheus",
+		Subsystem: "rule",
+		Name:      "group_load_errors_total",
+		Help:      "Total number of rule group loading failures, by source.",
+	}, []string{"source"})
+	if o.Registerer != nil {
+		o.Registerer.MustRegister(groupLoadErrors)
+	}
+
 	m := &Manager{
-		groups: map[string]*Group{},
-		opts:   o,
-		block:  make(chan struct{}),
-		done:   make(chan struct{}),
-		logger: o.Logger,
+		groups:          map[string]*Group{},
+		opts:            o,
+		block:           make(chan struct{}),
+		done:            make(chan struct{}),
+		groupLoadErrors: groupLoadErrors,
+		logger:          o.Logger,
 	}
 
 	return m
@@ -168,9 +232,80 @@ func NewManager(o *ManagerOptions) *Manager {
 func (m *Manager) Run() {
 	m.logger.Info("Starting rule manager...")
 	m.start()
+	m.mtx.RLock()
+	files := m.lastUpdate.files
+	m.mtx.RUnlock()
+	m.reconcileWatches(files)
 	<-m.done
 }
 
+// reconcileWatches starts, restarts, or stops the watch goroutine for every
+// GroupLoader in ManagerOptions.GroupLoaders that implements Watcher, so that
+// each scheme is always watching exactly the identifiers from the most
+// recent Update call. It is called both from Run (for the identifiers loaded
+// before Run started) and from Update itself (so that identifiers added,
+// removed, or changed on a later config reload take effect immediately,
+// rather than only ever watching what was current at startup).
+func (m *Manager) reconcileWatches(files []string) {
+	m.watchMtx.Lock()
+	defer m.watchMtx.Unlock()
+
+	if m.watchCancel == nil {
+		m.watchCancel = map[string]context.CancelFunc{}
+		m.watchedIDs = map[string]string{}
+	}
+
+	idsByScheme := map[string][]string{}
+	for _, id := range files {
+		if scheme := schemeFor(id); scheme != "" {
+			idsByScheme[scheme] = append(idsByScheme[scheme], id)
+		}
+	}
+
+	for scheme, loader := range m.opts.GroupLoaders {
+		watcher, ok := loader.(Watcher)
+		if !ok {
+			continue
+		}
+
+		identifiers := idsByScheme[scheme]
+		sig := strings.Join(identifiers, "\x00")
+		if sig == m.watchedIDs[scheme] {
+			// Same identifier set as already watched; leave the running
+			// goroutine in place instead of needlessly restarting it.
+			continue
+		}
+
+		if cancel, ok := m.watchCancel[scheme]; ok {
+			cancel()
+			delete(m.watchCancel, scheme)
+		}
+		delete(m.watchedIDs, scheme)
+
+		if len(identifiers) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(m.opts.Context)
+		m.watchCancel[scheme] = cancel
+		m.watchedIDs[scheme] = sig
+
+		go func(scheme string, watcher Watcher, identifiers []string) {
+			err := watcher.Watch(ctx, identifiers, func() {
+				m.mtx.RLock()
+				args := m.lastUpdate
+				m.mtx.RUnlock()
+				if err := m.Update(args.interval, args.files, args.externalLabels, args.externalURL, args.groupEvalIterationFunc); err != nil {
+					m.logger.Error("reloading rules after remote change failed", "scheme", scheme, "err", err)
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				m.logger.Error("watching rule group source failed", "scheme", scheme, "err", err)
+			}
+		}(scheme, watcher, identifiers)
+	}
+}
+
 func (m *Manager) start() {
 	close(m.block)
 }
@@ -180,6 +315,14 @@ func (m *Manager) Stop() {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
+	m.watchMtx.Lock()
+	for _, cancel := range m.watchCancel {
+		cancel()
+	}
+	m.watchCancel = nil
+	m.watchedIDs = nil
+	m.watchMtx.Unlock()
+
 	m.logger.Info("Stopping rule manager...")
 
 	for _, eg := range m.groups {
@@ -207,6 +350,14 @@ func (m *Manager) Update(interval time.Duration, files []string, externalLabels
 	default:
 	}
 
+	m.lastUpdate = lastUpdateArgs{
+		interval:               interval,
+		files:                  files,
+		externalLabels:         externalLabels,
+		externalURL:            externalURL,
+		groupEvalIterationFunc: groupEvalIterationFunc,
+	}
+
 	groups, errs := m.LoadGroups(interval, externalLabels, externalURL, groupEvalIterationFunc, files...)
 
 	if errs != nil {
@@ -271,6 +422,8 @@ func (m *Manager) Update(interval time.Duration, files []string, externalLabels
 	wg.Wait()
 	m.groups = groups
 
+	m.reconcileWatches(files)
+
 	return nil
 }
 
@@ -290,7 +443,52 @@ func (FileLoader) Load(identifier string) (*rulefmt.RuleGroups, []error) {
 
 func (FileLoader) Parse(query string) (parser.Expr, error) { return parser.ParseExpr(query) }
 
-// LoadGroups reads groups from a list of files.
+// Watcher is implemented by GroupLoader backends that can watch their own
+// identifiers for changes, such as RemoteGroupLoader polling a remote
+// source. Manager (from Update and Run) calls Watch, when implemented, for
+// each entry of ManagerOptions.GroupLoaders that has identifiers of its
+// scheme to watch, and triggers Manager.Update when onChange is invoked.
+// ManagerOptions.GroupLoader is not watched.
+type Watcher interface {
+	Watch(ctx context.Context, identifiers []string, onChange func()) error
+}
+
+// schemeFor returns the URL scheme of identifier, or "" if it has none (e.g.
+// a plain filesystem path), in which case it is handled by ManagerOptions.GroupLoader.
+func schemeFor(identifier string) string {
+	u, err := url.Parse(identifier)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme
+}
+
+// loaderFor returns the GroupLoader responsible for identifier and whether it
+// was dispatched to a scheme-specific entry from ManagerOptions.GroupLoaders
+// (isRemote) as opposed to falling back to the plain ManagerOptions.GroupLoader.
+func (m *Manager) loaderFor(identifier string) (loader GroupLoader, isRemote bool) {
+	if scheme := schemeFor(identifier); scheme != "" {
+		if l, ok := m.opts.GroupLoaders[scheme]; ok {
+			return l, true
+		}
+	}
+	return m.opts.GroupLoader, false
+}
+
+// LoadGroups reads groups from a list of rule group identifiers, which may be
+// plain file paths (handled by ManagerOptions.GroupLoader, as before) or
+// scheme-prefixed identifiers dispatched through ManagerOptions.GroupLoaders.
+// A plain file identifier that fails to load is still a fatal error for the
+// whole update, preserving existing behavior: a typo'd local rule file aborts
+// the reload and keeps the previous rule set intact. A scheme-dispatched
+// (remote) identifier that fails to load is instead skipped, keeping its
+// previous groups in place, and recorded via the
+// prometheus_rule_group_load_errors_total metric, since a transient fetch
+// problem for one remote source shouldn't block reloading every other
+// source. Every error returned by a scheme-dispatched GroupLoader's Load is
+// handled this way, including content that fails to parse or validate as a
+// rule file. An expression that the GroupLoader's Parse rejects after a
+// successful Load is a fatal error for either kind of identifier.
 func (m *Manager) LoadGroups(
 	interval time.Duration, externalLabels labels.Labels, externalURL string, groupEvalIterationFunc GroupEvalIterationFunc, filenames ...string,
 ) (map[string]*Group, []error) {
@@ -299,9 +497,26 @@ func (m *Manager) LoadGroups(
 	shouldRestore := !m.restored
 
 	for _, fn := range filenames {
-		rgs, errs := m.opts.GroupLoader.Load(fn)
+		loader, isRemote := m.loaderFor(fn)
+
+		rgs, errs := loader.Load(fn)
 		if errs != nil {
-			return nil, errs
+			if !isRemote {
+				return nil, errs
+			}
+
+			m.groupLoadErrors.WithLabelValues(schemeFor(fn)).Add(float64(len(errs)))
+			for _, e := range errs {
+				m.logger.Error("loading remote rule group failed, keeping previous rule set for this source", "source", fn, "err", e)
+			}
+			// Carry over whatever groups we already had for this identifier
+			// so Update doesn't tear them down over a transient load failure.
+			for gn, oldg := range m.groups {
+				if oldg.file == fn {
+					groups[gn] = oldg
+				}
+			}
+			continue
 		}
 
 		for _, rg := range rgs.Groups {
@@ -312,7 +527,7 @@ func (m *Manager) LoadGroups(
 
 			rules := make([]Rule, 0, len(rg.Rules))
 			for _, r := range rg.Rules {
-				expr, err := m.opts.GroupLoader.Parse(r.Expr.Value)
+				expr, err := loader.Parse(r.Expr.Value)
 				if err != nil {
 					return nil, []error{fmt.Errorf("%s: %w", fn, err)}
 				}
@@ -481,8 +696,11 @@ type RuleConcurrencyController interface {
 	// It is important that both *Group and Rule are not retained and only be used for the duration of the call.
 	Allow(ctx context.Context, group *Group, rule Rule) bool
 
-	// Done releases a concurrent evaluation slot.
-	Done(ctx context.Context)
+	// Done releases a concurrent evaluation slot acquired through a prior,
+	// successful call to Allow for the same group. group is passed so that
+	// controllers which partition concurrency (e.g. per tenant) know which
+	// partition to credit.
+	Done(ctx context.Context, group *Group)
 }
 
 // concurrentRuleEvalController holds a weighted semaphore which controls the concurrent evaluation of rules.
@@ -567,7 +785,7 @@ func (c *concurrentRuleEvalController) SplitGroupIntoBatches(_ context.Context,
 	return order
 }
 
-func (c *concurrentRuleEvalController) Done(_ context.Context) {
+func (c *concurrentRuleEvalController) Done(_ context.Context, _ *Group) {
 	c.sema.Release(1)
 }
 
@@ -588,7 +806,7 @@ func (c sequentialRuleEvalController) SplitGroupIntoBatches(_ context.Context, g
 	return order
 }
 
-func (c sequentialRuleEvalController) Done(_ context.Context) {}
+func (c sequentialRuleEvalController) Done(_ context.Context, _ *Group) {}
 
 // FromMaps returns new sorted Labels from the given maps, overriding each other in order.
 func FromMaps(maps ...map[string]string) labels.Labels {
@@ -0,0 +1,244 @@
+// Copyright 2013 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+)
+
+// defaultTenantSemaphoreGCInterval is how often MultitenantConcurrencyController
+// prunes per-tenant semaphores that are currently idle.
+const defaultTenantSemaphoreGCInterval = 10 * time.Minute
+
+// tenantSemaphore pairs a weighted semaphore with the capacity it was created
+// with and the number of slots currently in use, so idle tenants can be
+// detected and pruned without inspecting the semaphore's internal state.
+type tenantSemaphore struct {
+	sema  *semaphore.Weighted
+	limit int64
+	inUse int64
+}
+
+// MultitenantConcurrencyController is a RuleConcurrencyController that bounds
+// concurrent rule evaluation with two layers: a global cap shared by all
+// tenants, and a per-tenant cap looked up via TenantConcurrencyLimit. Tenant
+// identity is extracted from the evaluation context with TenantIDFromContext.
+//
+// Allow acquires the global slot first and then the tenant's slot; if the
+// tenant acquisition fails, the global slot is released again so it isn't
+// leaked. Per-tenant semaphores are created lazily on first use and pruned
+// periodically once they go idle, to avoid unbounded growth from
+// short-lived or one-off tenants.
+type MultitenantConcurrencyController struct {
+	global                 *semaphore.Weighted
+	tenantConcurrencyLimit func(tenantID string) int64
+	tenantIDFromContext    func(ctx context.Context) (string, error)
+	logger                 *slog.Logger
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantSemaphore
+
+	acquisitions *prometheus.CounterVec
+	rejections   *prometheus.CounterVec
+	queueDepth   *prometheus.GaugeVec
+}
+
+// NewMultitenantConcurrencyController returns a MultitenantConcurrencyController
+// enforcing maxConcurrentEvals globally and tenantConcurrencyLimit per tenant.
+// It starts a background goroutine, tied to ctx, that periodically prunes idle
+// per-tenant semaphores; the goroutine exits once ctx is done.
+func NewMultitenantConcurrencyController(
+	ctx context.Context,
+	reg prometheus.Registerer,
+	maxConcurrentEvals int64,
+	tenantConcurrencyLimit func(tenantID string) int64,
+	tenantIDFromContext func(ctx context.Context) (string, error),
+	logger *slog.Logger,
+) *MultitenantConcurrencyController {
+	if tenantIDFromContext == nil {
+		tenantIDFromContext = func(context.Context) (string, error) { return "", nil }
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	c := &MultitenantConcurrencyController{
+		global:                 semaphore.NewWeighted(maxConcurrentEvals),
+		tenantConcurrencyLimit: tenantConcurrencyLimit,
+		tenantIDFromContext:    tenantIDFromContext,
+		logger:                 logger,
+		tenants:                map[string]*tenantSemaphore{},
+		acquisitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "prometheus",
+			Subsystem: "rule",
+			Name:      "tenant_concurrent_acquisitions_total",
+			Help:      "Total number of concurrent rule evaluation slots acquired, by tenant.",
+		}, []string{"tenant"}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "prometheus",
+			Subsystem: "rule",
+			Name:      "tenant_concurrent_rejections_total",
+			Help:      "Total number of concurrent rule evaluation slots denied, by tenant.",
+		}, []string{"tenant"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "prometheus",
+			Subsystem: "rule",
+			Name:      "tenant_concurrent_queue_depth",
+			Help:      "Number of rule evaluations currently holding a concurrency slot, by tenant.",
+		}, []string{"tenant"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(c.acquisitions, c.rejections, c.queueDepth)
+	}
+
+	go c.gcLoop(ctx, defaultTenantSemaphoreGCInterval)
+
+	return c
+}
+
+func (c *MultitenantConcurrencyController) tenantLimit(tenantID string) int64 {
+	if c.tenantConcurrencyLimit == nil {
+		return math.MaxInt64
+	}
+	if limit := c.tenantConcurrencyLimit(tenantID); limit > 0 {
+		return limit
+	}
+	return math.MaxInt64
+}
+
+// Allow implements RuleConcurrencyController.
+func (c *MultitenantConcurrencyController) Allow(ctx context.Context, _ *Group, _ Rule) bool {
+	tenantID, err := c.tenantIDFromContext(ctx)
+	if err != nil {
+		c.logger.Error("failed to determine tenant for rule concurrency control", "err", err)
+		return false
+	}
+
+	if !c.global.TryAcquire(1) {
+		c.rejections.WithLabelValues(tenantID).Inc()
+		return false
+	}
+
+	// The tenant semaphore's lookup-or-create, its TryAcquire, and the inUse
+	// bookkeeping all happen under a single c.mtx critical section. gc() prunes
+	// tenants under the same mutex, so it can never observe (and delete) a
+	// tenant between its acquisition succeeding and inUse being incremented.
+	c.mtx.Lock()
+	ts, ok := c.tenants[tenantID]
+	if !ok {
+		ts = &tenantSemaphore{
+			sema:  semaphore.NewWeighted(c.tenantLimit(tenantID)),
+			limit: c.tenantLimit(tenantID),
+		}
+		c.tenants[tenantID] = ts
+	}
+	acquired := ts.sema.TryAcquire(1)
+	if acquired {
+		ts.inUse++
+	}
+	c.mtx.Unlock()
+
+	if !acquired {
+		c.global.Release(1)
+		c.rejections.WithLabelValues(tenantID).Inc()
+		return false
+	}
+
+	c.acquisitions.WithLabelValues(tenantID).Inc()
+	c.queueDepth.WithLabelValues(tenantID).Inc()
+	return true
+}
+
+// unknownTenantLabel is used for metrics when Done can't resolve the tenant
+// that a concurrency slot should be credited back to. The slot itself is
+// always released regardless, so this only ever affects label attribution.
+const unknownTenantLabel = "unknown"
+
+// Done implements RuleConcurrencyController. It always releases the global
+// slot a matching Allow call acquired, even if tenantIDFromContext fails this
+// time around: TenantIDFromContext is user-supplied and its signature allows
+// for transient errors, but Done has no "try again later" option, so skipping
+// the release here would permanently leak a concurrency slot.
+func (c *MultitenantConcurrencyController) Done(ctx context.Context, _ *Group) {
+	tenantID, err := c.tenantIDFromContext(ctx)
+	if err != nil {
+		c.logger.Error("failed to determine tenant releasing rule concurrency slot; releasing global slot only", "err", err)
+		c.global.Release(1)
+		c.queueDepth.WithLabelValues(unknownTenantLabel).Dec()
+		return
+	}
+
+	// Decrementing inUse and releasing the tenant semaphore happen under the
+	// same lock gc() uses, so we're always releasing the *tenantSemaphore that
+	// the matching Allow call acquired from, never one gc() has since replaced.
+	c.mtx.Lock()
+	ts, ok := c.tenants[tenantID]
+	if ok {
+		ts.inUse--
+		ts.sema.Release(1)
+	}
+	c.mtx.Unlock()
+
+	c.global.Release(1)
+
+	if !ok {
+		c.logger.Warn("Done called for unknown tenant, ignoring", "tenant", tenantID)
+		return
+	}
+
+	c.queueDepth.WithLabelValues(tenantID).Dec()
+}
+
+// SplitGroupIntoBatches implements RuleConcurrencyController by reusing the
+// dependency-aware batching of concurrentRuleEvalController; tenancy only
+// affects how many batches can run in parallel, not their ordering.
+func (c *MultitenantConcurrencyController) SplitGroupIntoBatches(ctx context.Context, g *Group) []ConcurrentRules {
+	cc := &concurrentRuleEvalController{sema: c.global, logger: c.logger}
+	return cc.SplitGroupIntoBatches(ctx, g)
+}
+
+// gcLoop periodically prunes tenant semaphores that are fully idle, so that
+// tenants which stop sending evaluations don't leak memory forever.
+func (c *MultitenantConcurrencyController) gcLoop(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			c.gc()
+		}
+	}
+}
+
+func (c *MultitenantConcurrencyController) gc() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for tenantID, ts := range c.tenants {
+		if ts.inUse == 0 {
+			delete(c.tenants, tenantID)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2013 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tenantCtxKey struct{}
+
+func withTenant(tenantID string) context.Context {
+	return context.WithValue(context.Background(), tenantCtxKey{}, tenantID)
+}
+
+func tenantFromCtx(ctx context.Context) (string, error) {
+	tenantID, _ := ctx.Value(tenantCtxKey{}).(string)
+	return tenantID, nil
+}
+
+func TestMultitenantConcurrencyController_GlobalAndTenantLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewMultitenantConcurrencyController(ctx, nil, 2, func(tenantID string) int64 {
+		if tenantID == "a" {
+			return 1
+		}
+		return 10
+	}, tenantFromCtx, nil)
+
+	ctxA, ctxB := withTenant("a"), withTenant("b")
+
+	require.True(t, c.Allow(ctxA, nil, nil))
+	// Tenant "a" has a limit of 1; a second acquisition for the same tenant must be denied.
+	require.False(t, c.Allow(ctxA, nil, nil))
+
+	// A different tenant can still acquire: the global cap of 2 isn't exhausted yet.
+	require.True(t, c.Allow(ctxB, nil, nil))
+	// Now the global cap (1 from "a" + 1 from "b") is exhausted.
+	require.False(t, c.Allow(ctxB, nil, nil))
+
+	c.Done(ctxA, nil)
+	// Releasing "a" frees a global slot, so "b" can acquire a second one.
+	require.True(t, c.Allow(ctxB, nil, nil))
+
+	c.Done(ctxB, nil)
+	c.Done(ctxB, nil)
+}
+
+func TestMultitenantConcurrencyController_GCPrunesIdleTenants(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewMultitenantConcurrencyController(ctx, nil, 10, nil, tenantFromCtx, nil)
+
+	tctx := withTenant("tenant")
+	require.True(t, c.Allow(tctx, nil, nil))
+
+	c.gc()
+	require.Len(t, c.tenants, 1, "gc must not prune a tenant with an in-flight acquisition")
+
+	c.Done(tctx, nil)
+	c.gc()
+	require.Empty(t, c.tenants, "gc must prune a tenant once it's fully idle")
+}
+
+func TestMultitenantConcurrencyController_DoneReleasesGlobalSlotOnTenantLookupError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	failLookup := false
+	tenantIDFromContext := func(context.Context) (string, error) {
+		if failLookup {
+			return "", errors.New("tenant lookup unavailable")
+		}
+		return "tenant", nil
+	}
+
+	c := NewMultitenantConcurrencyController(ctx, nil, 1, nil, tenantIDFromContext, nil)
+
+	require.True(t, c.Allow(context.Background(), nil, nil))
+	require.False(t, c.Allow(context.Background(), nil, nil), "global cap of 1 should already be exhausted")
+
+	failLookup = true
+	c.Done(context.Background(), nil)
+
+	failLookup = false
+	require.True(t, c.Allow(context.Background(), nil, nil), "Done must release the global slot even when tenant lookup fails")
+}
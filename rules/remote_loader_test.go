@@ -0,0 +1,150 @@
+// Copyright 2013 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rules
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testRuleGroupYAML = `groups:
+- name: example
+  rules:
+  - record: job:http_requests:rate5m
+    expr: sum(rate(http_requests_total[5m])) by (job)
+`
+
+// fakeFetcher returns payloads[min(calls, len(payloads)-1)] on each Fetch,
+// so a test can script a sequence of responses by index.
+type fakeFetcher struct {
+	mu       sync.Mutex
+	payloads [][]byte
+	calls    int
+}
+
+func (f *fakeFetcher) Fetch(_ context.Context, _, _ string) ([]byte, string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := f.calls
+	if idx >= len(f.payloads) {
+		idx = len(f.payloads) - 1
+	}
+	f.calls++
+	return f.payloads[idx], fmt.Sprintf("etag-%d", idx), false, nil
+}
+
+func TestRemoteGroupLoader_Load(t *testing.T) {
+	fetcher := &fakeFetcher{payloads: [][]byte{[]byte(testRuleGroupYAML)}}
+	l := NewRemoteGroupLoader(map[string]Fetcher{"https": fetcher}, time.Minute, nil)
+
+	groups, errs := l.Load("https://rules.example.com/team-a.yml")
+	require.Nil(t, errs)
+	require.Len(t, groups.Groups, 1)
+	require.Equal(t, "example", groups.Groups[0].Name)
+}
+
+func TestRemoteGroupLoader_NoFetcherForScheme(t *testing.T) {
+	l := NewRemoteGroupLoader(map[string]Fetcher{}, time.Minute, nil)
+
+	_, errs := l.Load("s3://bucket/team-a.yml")
+	require.NotEmpty(t, errs)
+}
+
+func TestRemoteGroupLoader_UnchangedContentIsNotReparsed(t *testing.T) {
+	// Two fetches returning byte-identical content but different ETags, as a
+	// backend might when only cache-control metadata changed.
+	fetcher := &fakeFetcher{payloads: [][]byte{[]byte(testRuleGroupYAML), []byte(testRuleGroupYAML)}}
+	l := NewRemoteGroupLoader(map[string]Fetcher{"https": fetcher}, time.Minute, nil)
+
+	id := "https://rules.example.com/team-a.yml"
+	first, errs := l.Load(id)
+	require.Nil(t, errs)
+
+	second, errs := l.Load(id)
+	require.Nil(t, errs)
+	require.Same(t, first, second, "identical content should return the cached parse result rather than reparsing")
+}
+
+func TestRemoteGroupLoader_BackoffGrowsAndExpires(t *testing.T) {
+	l := NewRemoteGroupLoader(nil, time.Minute, nil)
+	l.minBackoff = time.Millisecond
+	l.maxBackoff = 4 * time.Millisecond
+
+	require.False(t, l.inBackoff("x"), "an identifier with no recorded failures is never in backoff")
+
+	l.recordFailure("x")
+	require.Equal(t, time.Millisecond, l.cache["x"].backoffDuration)
+	require.True(t, l.inBackoff("x"))
+
+	l.recordFailure("x")
+	require.Equal(t, 2*time.Millisecond, l.cache["x"].backoffDuration)
+
+	l.recordFailure("x")
+	require.Equal(t, 4*time.Millisecond, l.cache["x"].backoffDuration, "backoff must cap at maxBackoff")
+
+	// Once nextRetry is in the past, the identifier must be eligible for
+	// polling again: backoff is a window that expires, not a permanent gate.
+	l.mtx.Lock()
+	l.cache["x"].nextRetry = time.Now().Add(-time.Second)
+	l.mtx.Unlock()
+	require.False(t, l.inBackoff("x"), "an expired backoff window must not block polling forever")
+
+	l.recordFailure("x")
+	l.clearBackoff("x")
+	require.False(t, l.inBackoff("x"))
+	require.Equal(t, time.Duration(0), l.cache["x"].backoffDuration)
+}
+
+func TestRemoteGroupLoader_WatchTriggersOnChange(t *testing.T) {
+	groupA := []byte("groups:\n- name: a\n  rules:\n  - record: a\n    expr: vector(1)\n")
+	groupB := []byte("groups:\n- name: a\n  rules:\n  - record: a\n    expr: vector(2)\n")
+
+	fetcher := &fakeFetcher{payloads: [][]byte{groupA, groupA, groupB}}
+	l := NewRemoteGroupLoader(map[string]Fetcher{"http": fetcher}, 5*time.Millisecond, nil)
+
+	// Load once up front so groupA is already cached: the first poll then
+	// sees unchanged content, and only the switch to groupB is a change.
+	id := "http://rules.example.com/team-a.yml"
+	_, errs := l.Load(id)
+	require.Nil(t, errs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Watch(ctx, []string{id}, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Watch to report a change")
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}